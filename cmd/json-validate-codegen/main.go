@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/json-validate/json-validate-codegen"
+	"github.com/json-validate/json-validate-codegen/golang"
 	"github.com/json-validate/json-validate-codegen/typescript"
 	"github.com/json-validate/json-validate-go"
 
@@ -17,6 +19,7 @@ type outputLang int
 
 const (
 	outputLangTypeScript outputLang = iota
+	outputLangGo
 )
 
 func main() {
@@ -29,6 +32,20 @@ func main() {
 			Value: "typescript",
 			Usage: "language to output",
 		},
+		cli.StringFlag{
+			Name:  "package",
+			Value: "main",
+			Usage: "package name to use, for languages that have packages",
+		},
+		cli.StringFlag{
+			Name:  "emit",
+			Value: "types",
+			Usage: "comma-separated list of artifacts to emit: types, validators",
+		},
+		cli.StringFlag{
+			Name:  "out-dir",
+			Usage: "directory to write one file per type into, instead of printing everything to stdout",
+		},
 	}
 
 	app.Action = func(c *cli.Context) error {
@@ -37,11 +54,26 @@ func main() {
 		switch c.String("lang") {
 		case "typescript":
 			lang = outputLangTypeScript
+		case "go":
+			lang = outputLangGo
 		default:
 			return fmt.Errorf("unknown lang: %#v", c.String("lang"))
 		}
 
-		return run(c.Args(), lang)
+		emit, err := parseEmit(c.String("emit"))
+		if err != nil {
+			return err
+		}
+
+		if lang == outputLangGo {
+			for _, kind := range emit {
+				if kind == codegen.EmitKindValidators {
+					return fmt.Errorf("--lang go does not support --emit=validators yet")
+				}
+			}
+		}
+
+		return run(c.Args(), lang, c.String("package"), emit, c.String("out-dir"))
 	}
 
 	err := app.Run(os.Args)
@@ -50,7 +82,25 @@ func main() {
 	}
 }
 
-func run(schemaPaths []string, lang outputLang) error {
+// parseEmit parses the comma-separated value of the --emit flag into the
+// EmitKinds codegen.Encoder expects.
+func parseEmit(s string) ([]codegen.EmitKind, error) {
+	var emit []codegen.EmitKind
+	for _, part := range strings.Split(s, ",") {
+		switch part {
+		case "types":
+			emit = append(emit, codegen.EmitKindTypes)
+		case "validators":
+			emit = append(emit, codegen.EmitKindValidators)
+		default:
+			return nil, fmt.Errorf("unknown --emit value: %#v", part)
+		}
+	}
+
+	return emit, nil
+}
+
+func run(schemaPaths []string, lang outputLang, packageName string, emit []codegen.EmitKind, outDir string) error {
 	schemas := make([]jsonvalidate.SchemaStruct, len(schemaPaths))
 	for i, schemaPath := range schemaPaths {
 		reader, err := os.Open(schemaPath)
@@ -71,11 +121,29 @@ func run(schemaPaths []string, lang outputLang) error {
 		return err
 	}
 
+	var fs codegen.FileSystem
+	if outDir != "" {
+		fs = &codegen.DirFS{Dir: outDir}
+	} else {
+		fs = codegen.SingleWriterFS{Out: os.Stdout}
+	}
+
 	encoder := codegen.Encoder{
-		Out:      os.Stdout,
-		Registry: registry,
-		Emitter:  &typescript.Emitter{},
+		FS:          fs,
+		PackageName: packageName,
+		Emit:        emit,
+		Registry:    registry,
+		Emitter:     emitter(lang),
 	}
 
 	return encoder.Run()
 }
+
+func emitter(lang outputLang) codegen.Emitter {
+	switch lang {
+	case outputLangGo:
+		return &golang.Emitter{}
+	default:
+		return &typescript.Emitter{}
+	}
+}
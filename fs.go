@@ -0,0 +1,124 @@
+package codegen
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// FileSystem abstracts where an Emitter writes its generated files, so that
+// an Emitter can split its output across multiple files (one per emitted
+// type) instead of being limited to a single io.Writer.
+type FileSystem interface {
+	// OpenFile returns a writer for the file named name, creating it if it
+	// doesn't already exist. Callers are responsible for closing the
+	// returned writer once they're done writing to it.
+	OpenFile(name string) (io.WriteCloser, error)
+}
+
+// MultiFile may optionally be implemented by a FileSystem to advertise that
+// each distinct name given to OpenFile produces its own independent file,
+// unlike e.g. SingleWriterFS, which concatenates every name onto one shared
+// writer. An Emitter that must repeat per-file boilerplate -- such as a Go
+// package clause -- in every file it creates can check for this (see
+// FilePreamble) to decide whether that boilerplate needs repeating.
+type MultiFile interface {
+	IsMultiFile() bool
+}
+
+// DirFS is a FileSystem that writes each named file to its own path within
+// Dir, creating Dir if it doesn't already exist. Multiple calls to OpenFile
+// with the same name -- e.g. a type and its validator sharing one generated
+// file -- append to what's already been written there rather than
+// truncating it.
+type DirFS struct {
+	Dir string
+
+	opened map[string]bool
+}
+
+func (fs *DirFS) OpenFile(name string) (io.WriteCloser, error) {
+	if err := os.MkdirAll(fs.Dir, 0777); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(fs.Dir, name)
+
+	if fs.opened == nil {
+		fs.opened = map[string]bool{}
+	}
+
+	if fs.opened[name] {
+		return os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0666)
+	}
+
+	fs.opened[name] = true
+	return os.Create(path)
+}
+
+func (fs *DirFS) IsMultiFile() bool {
+	return true
+}
+
+// SingleWriterFS is a FileSystem that ignores the requested file name and
+// concatenates every write onto a single underlying io.Writer, such as
+// os.Stdout. This preserves Encoder's pre-multi-file behavior of writing
+// everything it generates to one stream.
+type SingleWriterFS struct {
+	Out io.Writer
+}
+
+func (fs SingleWriterFS) OpenFile(name string) (io.WriteCloser, error) {
+	return nopCloser{fs.Out}, nil
+}
+
+func (fs SingleWriterFS) IsMultiFile() bool {
+	return false
+}
+
+// nopCloser adapts an io.Writer into an io.WriteCloser whose Close is a
+// no-op, since SingleWriterFS's underlying writer is shared across every
+// file Encoder opens and outlives any single one of them.
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// FilePreamble tracks whether per-file boilerplate -- such as a package
+// clause or a validator helper preamble -- has already been written to a
+// given file, so that an Emitter can write it once per file under a
+// MultiFile FileSystem (e.g. one `package foo` line per generated Go file),
+// or once overall otherwise (preserving a single combined header when
+// everything is concatenated onto one writer).
+type FilePreamble struct {
+	wroteGlobal bool
+	wroteFile   map[string]bool
+}
+
+// Ensure calls write the first time file is seen under fs, and is a no-op on
+// every subsequent call for that same file (or, under a FileSystem that
+// isn't MultiFile, every subsequent call regardless of file).
+func (p *FilePreamble) Ensure(fs FileSystem, file string, write func() error) error {
+	if mf, ok := fs.(MultiFile); ok && mf.IsMultiFile() {
+		if p.wroteFile == nil {
+			p.wroteFile = map[string]bool{}
+		}
+
+		if p.wroteFile[file] {
+			return nil
+		}
+
+		p.wroteFile[file] = true
+	} else {
+		if p.wroteGlobal {
+			return nil
+		}
+
+		p.wroteGlobal = true
+	}
+
+	return write()
+}
@@ -2,7 +2,7 @@ package codegen
 
 import (
 	"errors"
-	"io"
+	"fmt"
 	"net/url"
 
 	"github.com/json-validate/json-validate-go"
@@ -13,16 +13,97 @@ import (
 // Encoder works by walking across the schemas in a Registry, and calling out to
 // an underlying Emitter to produce code for the particular language at hand.
 type Encoder struct {
-	// Where Encoder should produce its output.
-	Out io.Writer
+	// FS is where Encoder's Emitter writes the files it generates. Use
+	// SingleWriterFS to concatenate everything onto a single io.Writer (e.g.
+	// os.Stdout), or DirFS to write one file per emitted type into a
+	// directory.
+	FS FileSystem
+
+	// The name of the package the generated code belongs to, if the target
+	// language has a notion of packages. Emitters that don't need this may
+	// ignore it.
+	PackageName string
+
+	// What Run should produce. If empty, defaults to emitting just types.
+	// EmitKindValidators requires Emitter to also implement ValidatorEmitter.
+	Emit []EmitKind
 
 	// The registry to walk over.
 	Registry jsonvalidate.Registry
 
 	// The emitter handling the specifics of the target language.
 	Emitter Emitter
+
+	// usedNames tracks every name returned by the Emitter so far, so that a
+	// user-supplied `metadata.typeName` colliding with another type (whether
+	// user-supplied or synthesised) can be caught instead of silently
+	// clobbering a previous declaration.
+	usedNames map[string]bool
+
+	// refs memoizes the walkResult of each `definitions` entry, keyed by the
+	// defining schema's ID and the definition's name, so that a definition
+	// referenced from multiple places is only walked and emitted once.
+	refs map[refKey]walkResult
+
+	// refsInProgress tracks definitions that are currently being walked, so
+	// that a recursive reference back to one of them can resolve to its name
+	// instead of recursing forever.
+	refsInProgress map[refKey]bool
+
+	// formats holds handlers registered via RegisterFormat, consulted before
+	// falling back to the Emitter's own FormatEmitter implementation (if
+	// any) for a given `metadata.format` value.
+	formats map[string]FormatHandler
+
+	// formatNames memoizes the type name emitted for each (PrimitiveKind,
+	// format) pair, so that e.g. every "date-time" string in a schema
+	// reuses one declaration instead of emitting it repeatedly.
+	formatNames map[formatKey]string
+}
+
+// formatKey identifies one `metadata.format` value applied to one
+// underlying primitive kind.
+type formatKey struct {
+	Kind   PrimitiveKind
+	Format string
+}
+
+// FormatHandler produces the type to use in place of a plain primitive for
+// a `metadata.format` value, emitting whatever declaration it needs (e.g. a
+// branded type) via fs.
+type FormatHandler func(fs FileSystem, format string) (string, error)
+
+// RegisterFormat registers handler to be consulted whenever `metadata.format`
+// is set to format, taking precedence over the Emitter's own FormatEmitter
+// implementation (if any). Call this after constructing Encoder but before
+// Run().
+func (e *Encoder) RegisterFormat(format string, handler FormatHandler) {
+	if e.formats == nil {
+		e.formats = map[string]FormatHandler{}
+	}
+
+	e.formats[format] = handler
+}
+
+// refKey identifies a single `definitions` entry within the schema that
+// declares it.
+type refKey struct {
+	SchemaID string
+	Ref      string
 }
 
+// EmitKind selects one artifact Run should produce.
+type EmitKind string
+
+const (
+	// EmitKindTypes selects emitting the generated type declarations.
+	EmitKindTypes EmitKind = "types"
+
+	// EmitKindValidators selects emitting standalone runtime validation
+	// functions alongside the generated types.
+	EmitKindValidators EmitKind = "validators"
+)
+
 type NamePath struct {
 	SchemaID *url.URL
 	Segments []NamePathSegment
@@ -44,32 +125,69 @@ type NamePathSegment struct {
 }
 
 type Struct struct {
-	Path               *NamePath
+	Path *NamePath
+
+	// Name is the user-supplied name for this type, taken from
+	// `metadata.typeName`. It is empty if the schema did not set one, in
+	// which case the Emitter should fall back to synthesising a name from
+	// Path.
+	Name string
+
+	// Description is the user-supplied `metadata.description`, if any.
+	Description string
+
 	RequiredProperties map[string]string
 	OptionalProperties map[string]string
+
+	// PropertyNames maps each property's JSON name to the identifier the
+	// Emitter should use for it, honoring `metadata.propertyNames.<field>`
+	// when present and falling back to the JSON name otherwise. Only safe
+	// for an Emitter whose type declarations carry the wire name alongside
+	// the identifier (e.g. a Go struct tag); an Emitter whose field name
+	// doubles as the wire key (e.g. a TypeScript interface) should ignore
+	// this and use the JSON name as-is.
+	PropertyNames map[string]string
 }
 
 type Array struct {
-	Path     *NamePath
-	Elements string
+	Path        *NamePath
+	Name        string
+	Description string
+	Elements    string
 }
 
 type Values struct {
-	Path   *NamePath
-	Values string
+	Path        *NamePath
+	Name        string
+	Description string
+	Values      string
 }
 
 type Variant struct {
 	Path               *NamePath
+	Name               string
+	Description        string
 	TagName            string
 	TagValue           string
 	RequiredProperties map[string]string
 	OptionalProperties map[string]string
+	PropertyNames      map[string]string
 }
 
 type Union struct {
-	Path     *NamePath
+	Path        *NamePath
+	Name        string
+	Description string
+
+	// TagName is the discriminator property name shared by every variant.
+	TagName string
+
 	Variants []string
+
+	// VariantsByTag maps each discriminator tag value to the corresponding
+	// entry in Variants, for emitters that need to dispatch on the tag
+	// value (e.g. a union validator).
+	VariantsByTag map[string]string
 }
 
 // Emitter handles producing code for a particular target language.
@@ -89,29 +207,123 @@ type Emitter interface {
 	// PrimitiveString returns the name of the "string" type.
 	PrimitiveString() string
 
+	// WrapNullable returns the type expression to use in place of inner for a
+	// schema with `nullable: true`, e.g. "T | null" in TypeScript or "*T" in
+	// Go.
+	WrapNullable(inner string) string
+
 	// EmitStruct outputs a representation of a struct, returning the name of the
 	// emitted struct type.
-	EmitStruct(io.Writer, Struct) (string, error)
+	EmitStruct(FileSystem, Struct) (string, error)
 
 	// EmitArray outputs a representation of an array, returning the name of the
 	// emitted array type.
-	EmitArray(io.Writer, Array) (string, error)
+	EmitArray(FileSystem, Array) (string, error)
 
 	// EmitValues outputs a representation of a dictionary, returning the name of
 	// the emitted dictionary type.
-	EmitValues(io.Writer, Values) (string, error)
+	EmitValues(FileSystem, Values) (string, error)
 
 	// EmitVariant outputs a representation of a struct that is a variant of a
 	// discriminated union, returning the name of the emitted type.
-	EmitVariant(io.Writer, Variant) (string, error)
+	EmitVariant(FileSystem, Variant) (string, error)
 
 	// EmitUnion outputs a representation of a discriminated union, returning the
 	// name of the emitted type.
-	EmitUnion(io.Writer, Union) (string, error)
+	EmitUnion(FileSystem, Union) (string, error)
+}
+
+// HeaderEmitter may optionally be implemented by an Emitter that needs to
+// know the package name ahead of emitting any types, such as to write it
+// into a package declaration or imports. It's called once, before Run walks
+// the registry.
+type HeaderEmitter interface {
+	EmitHeader(fs FileSystem, packageName string) error
+}
+
+// FormatEmitter may optionally be implemented by an Emitter that knows how
+// to turn a `metadata.format` string (such as "date-time" or "uuid") on a
+// primitive schema into a more specific type, such as a branded TypeScript
+// type or Go's time.Time.
+type FormatEmitter interface {
+	// EmitFormattedPrimitive returns the type to use in place of the plain
+	// primitive named by base, for the given format, emitting whatever
+	// declaration it needs (if any) via fs.
+	EmitFormattedPrimitive(fs FileSystem, base PrimitiveKind, format string) (string, error)
+}
+
+// PrimitiveKind identifies one of JSON Validate's primitive schema kinds, for
+// the purposes of validator generation.
+type PrimitiveKind int
+
+const (
+	PrimitiveKindEmpty PrimitiveKind = iota
+	PrimitiveKindNull
+	PrimitiveKindBoolean
+	PrimitiveKindNumber
+	PrimitiveKindString
+)
+
+// ValidatorEmitter may optionally be implemented by an Emitter that can also
+// produce standalone runtime validation functions alongside its types. Each
+// EmitXValidator method mirrors its EmitX counterpart: it's called with the
+// same kind of data, except that every field that names a subschema's type
+// for EmitX (e.g. Array.Elements, or Union.Variants) instead holds that
+// subschema's validator expression, and should emit a function that walks a
+// value of the
+// corresponding shape and returns validation errors, rather than a type
+// declaration. The generated validation code should be self-contained and
+// require no runtime dependency.
+type ValidatorEmitter interface {
+	// EmitPrimitiveValidator returns an expression, or the name of a helper
+	// function, that validates a value against the given primitive kind.
+	EmitPrimitiveValidator(kind PrimitiveKind) (string, error)
+
+	// EmitArrayValidator outputs a validator for an array, returning the name
+	// of the emitted validator function.
+	EmitArrayValidator(FileSystem, Array) (string, error)
+
+	// EmitStructValidator outputs a validator for a struct, returning the name
+	// of the emitted validator function.
+	EmitStructValidator(FileSystem, Struct) (string, error)
+
+	// EmitValuesValidator outputs a validator for a dictionary, returning the
+	// name of the emitted validator function.
+	EmitValuesValidator(FileSystem, Values) (string, error)
+
+	// EmitVariantValidator outputs a validator for a variant of a
+	// discriminated union, returning the name of the emitted validator
+	// function.
+	EmitVariantValidator(FileSystem, Variant) (string, error)
+
+	// EmitUnionValidator outputs a validator for a discriminated union,
+	// returning the name of the emitted validator function.
+	EmitUnionValidator(FileSystem, Union) (string, error)
+
+	// WrapNullableValidator returns the expression to use in place of inner
+	// for a schema with `nullable: true`, accepting null in addition to
+	// whatever inner itself validates. Mirrors Emitter.WrapNullable.
+	WrapNullableValidator(inner string) string
 }
 
 // Run triggers the code generation process.
 func (e *Encoder) Run() error {
+	if he, ok := e.Emitter.(HeaderEmitter); ok {
+		if err := he.EmitHeader(e.FS, e.PackageName); err != nil {
+			return err
+		}
+	}
+
+	if e.wantValidators() {
+		if _, ok := e.Emitter.(ValidatorEmitter); !ok {
+			return fmt.Errorf("json-validate-codegen: %T does not implement ValidatorEmitter", e.Emitter)
+		}
+	}
+
+	e.usedNames = map[string]bool{}
+	e.refs = map[refKey]walkResult{}
+	e.refsInProgress = map[refKey]bool{}
+
 	for _, schema := range e.Registry.Schemas {
 		path := NamePath{SchemaID: schema.ID, Segments: []NamePathSegment{}}
 		if _, err := e.walk(&path, schema); err != nil {
@@ -122,137 +334,574 @@ func (e *Encoder) Run() error {
 	return nil
 }
 
-func (e *Encoder) walk(path *NamePath, schema *jsonvalidate.Schema) (string, error) {
+// wantTypes reports whether Run should emit type declarations. It defaults
+// to true, so that Emit need not be set for the common case of only wanting
+// types.
+func (e *Encoder) wantTypes() bool {
+	if len(e.Emit) == 0 {
+		return true
+	}
+
+	for _, kind := range e.Emit {
+		if kind == EmitKindTypes {
+			return true
+		}
+	}
+
+	return false
+}
+
+// wantValidators reports whether Run should also emit validator functions.
+func (e *Encoder) wantValidators() bool {
+	for _, kind := range e.Emit {
+		if kind == EmitKindValidators {
+			return true
+		}
+	}
+
+	return false
+}
+
+// claimName records that name has been emitted, returning an error if it was
+// already claimed by an earlier type. This catches collisions between
+// user-supplied `metadata.typeName` values (and between those and
+// synthesised names) that would otherwise silently produce two declarations
+// with the same name.
+func (e *Encoder) claimName(name string) error {
+	if e.usedNames[name] {
+		return fmt.Errorf("json-validate-codegen: duplicate type name %#v; set a unique `metadata.typeName` to disambiguate", name)
+	}
+
+	e.usedNames[name] = true
+	return nil
+}
+
+// metadataTypeName reads `metadata.typeName` off of schema, returning "" if
+// it isn't set.
+func metadataTypeName(schema *jsonvalidate.Schema) string {
+	name, _ := schema.Metadata["typeName"].(string)
+	return name
+}
+
+// metadataDescription reads `metadata.description` off of schema, returning
+// "" if it isn't set.
+func metadataDescription(schema *jsonvalidate.Schema) string {
+	description, _ := schema.Metadata["description"].(string)
+	return description
+}
+
+// metadataFormat reads `metadata.format` off of schema, returning "" if it
+// isn't set.
+func metadataFormat(schema *jsonvalidate.Schema) string {
+	format, _ := schema.Metadata["format"].(string)
+	return format
+}
+
+// metadataPropertyName reads `metadata.propertyNames.<property>` off of
+// schema, falling back to property itself if it isn't set.
+func metadataPropertyName(schema *jsonvalidate.Schema, property string) string {
+	propertyNames, _ := schema.Metadata["propertyNames"].(map[string]interface{})
+	if name, ok := propertyNames[property].(string); ok && name != "" {
+		return name
+	}
+
+	return property
+}
+
+// idString renders a schema ID for use as a map key, tolerating a nil ID
+// (the common case of a single, anonymous root schema).
+func idString(id *url.URL) string {
+	if id == nil {
+		return ""
+	}
+
+	return id.String()
+}
+
+// resolveRef looks up the definition named ref, preferring the definitions
+// of the schema identified by schemaID and falling back to any other
+// schema in the Registry that declares it, to support refs shared across
+// schema documents. It returns the ID of the schema that actually declared
+// the definition (which may differ from schemaID), so that callers can
+// memoize by the definition's true owner rather than whoever referenced it.
+func (e *Encoder) resolveRef(schemaID *url.URL, ref string) (*jsonvalidate.Schema, *url.URL, error) {
+	for _, schema := range e.Registry.Schemas {
+		if idString(schema.ID) != idString(schemaID) {
+			continue
+		}
+
+		if def, ok := schema.Definitions[ref]; ok {
+			return def, schema.ID, nil
+		}
+	}
+
+	for _, schema := range e.Registry.Schemas {
+		if def, ok := schema.Definitions[ref]; ok {
+			return def, schema.ID, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("json-validate-codegen: no definition named %#v", ref)
+}
+
+// walkRef resolves a `ref` schema to the definition it names, walking and
+// emitting that definition the first time it's seen and returning the
+// memoized result on every subsequent reference. The memo (and in-progress)
+// key is the defining schema's ID, not the referencing schema's, so that a
+// definition shared by several schema documents is only walked once no
+// matter which of them references it. A ref encountered while its own
+// definition is still being walked (i.e. a recursive schema) resolves
+// immediately to the definition's `metadata.typeName`, which must be set to
+// give the recursive reference something to name itself after.
+func (e *Encoder) walkRef(path *NamePath, schema *jsonvalidate.Schema) (walkResult, error) {
+	def, defID, err := e.resolveRef(path.SchemaID, schema.Ref)
+	if err != nil {
+		return walkResult{}, err
+	}
+
+	key := refKey{SchemaID: idString(defID), Ref: schema.Ref}
+
+	if result, ok := e.refs[key]; ok {
+		return result, nil
+	}
+
+	if e.refsInProgress[key] {
+		name := metadataTypeName(def)
+		if name == "" {
+			return walkResult{}, fmt.Errorf("json-validate-codegen: recursive definition %#v requires a `metadata.typeName` to break the cycle", schema.Ref)
+		}
+
+		return walkResult{TypeName: name}, nil
+	}
+
+	e.refsInProgress[key] = true
+	defPath := &NamePath{SchemaID: path.SchemaID, Segments: []NamePathSegment{{Property: schema.Ref}}}
+	result, err := e.walk(defPath, def)
+	delete(e.refsInProgress, key)
+	if err != nil {
+		return walkResult{}, err
+	}
+
+	e.refs[key] = result
+	return result, nil
+}
+
+// walkResult carries everything produced by walking a single schema: the
+// name of its emitted type, and, if validators were requested, the name of
+// its emitted validator function.
+type walkResult struct {
+	TypeName      string
+	ValidatorName string
+}
+
+// walk computes the emitted type (and, if requested, validator) for schema,
+// applying the `nullable` modifier (which JSON Validate allows on any schema
+// kind) to the result of walkKind.
+func (e *Encoder) walk(path *NamePath, schema *jsonvalidate.Schema) (walkResult, error) {
+	result, err := e.walkKind(path, schema)
+	if err != nil {
+		return walkResult{}, err
+	}
+
+	if schema.Nullable && e.wantTypes() {
+		result.TypeName = e.Emitter.WrapNullable(result.TypeName)
+	}
+
+	if schema.Nullable && e.wantValidators() {
+		result.ValidatorName = e.Emitter.(ValidatorEmitter).WrapNullableValidator(result.ValidatorName)
+	}
+
+	return result, nil
+}
+
+func (e *Encoder) walkKind(path *NamePath, schema *jsonvalidate.Schema) (walkResult, error) {
 	switch schema.Kind {
+	case jsonvalidate.SchemaKindRef:
+		return e.walkRef(path, schema)
 	case jsonvalidate.SchemaKindEmpty:
-		return e.Emitter.PrimitiveEmpty(), nil
+		return e.walkPrimitive(PrimitiveKindEmpty, e.Emitter.PrimitiveEmpty())
 	case jsonvalidate.SchemaKindType:
 		switch schema.Type {
 		case jsonvalidate.SchemaTypeNull:
-			return e.Emitter.PrimitiveNull(), nil
+			return e.walkPrimitiveFormatted(schema, PrimitiveKindNull, e.Emitter.PrimitiveNull())
 		case jsonvalidate.SchemaTypeBoolean:
-			return e.Emitter.PrimitiveBoolean(), nil
+			return e.walkPrimitiveFormatted(schema, PrimitiveKindBoolean, e.Emitter.PrimitiveBoolean())
 		case jsonvalidate.SchemaTypeNumber:
-			return e.Emitter.PrimitiveNumber(), nil
+			return e.walkPrimitiveFormatted(schema, PrimitiveKindNumber, e.Emitter.PrimitiveNumber())
 		case jsonvalidate.SchemaTypeString:
-			return e.Emitter.PrimitiveString(), nil
+			return e.walkPrimitiveFormatted(schema, PrimitiveKindString, e.Emitter.PrimitiveString())
 		}
 	case jsonvalidate.SchemaKindElements:
 		path.Push(NamePathSegment{Elements: true})
-		name, err := e.walk(path, schema.Elements)
+		elements, err := e.walk(path, schema.Elements)
 		if err != nil {
-			return "", err
+			return walkResult{}, err
 		}
 
 		path.Pop()
 
-		return e.Emitter.EmitArray(e.Out, Array{
-			Path:     path,
-			Elements: name,
-		})
+		result := walkResult{}
+
+		if e.wantTypes() {
+			arrayName, err := e.Emitter.EmitArray(e.FS, Array{
+				Path:        path,
+				Name:        metadataTypeName(schema),
+				Description: metadataDescription(schema),
+				Elements:    elements.TypeName,
+			})
+			if err != nil {
+				return walkResult{}, err
+			}
+
+			if err := e.claimName(arrayName); err != nil {
+				return walkResult{}, err
+			}
+
+			result.TypeName = arrayName
+		}
+
+		if e.wantValidators() {
+			validatorName, err := e.Emitter.(ValidatorEmitter).EmitArrayValidator(e.FS, Array{
+				Path:        path,
+				Name:        metadataTypeName(schema),
+				Description: metadataDescription(schema),
+				Elements:    elements.ValidatorName,
+			})
+			if err != nil {
+				return walkResult{}, err
+			}
+
+			result.ValidatorName = validatorName
+		}
+
+		return result, nil
 	case jsonvalidate.SchemaKindProperties:
-		required := map[string]string{}
+		requiredTypes := map[string]string{}
+		requiredValidators := map[string]string{}
+		propertyNames := map[string]string{}
 		for key, value := range schema.Properties {
 			path.Push(NamePathSegment{Property: key})
-			name, err := e.walk(path, value)
+			property, err := e.walk(path, value)
 			if err != nil {
-				return "", err
+				return walkResult{}, err
 			}
 
-			required[key] = name
+			requiredTypes[key] = property.TypeName
+			requiredValidators[key] = property.ValidatorName
+			propertyNames[key] = metadataPropertyName(schema, key)
 			path.Pop()
 		}
 
-		optional := map[string]string{}
+		optionalTypes := map[string]string{}
+		optionalValidators := map[string]string{}
 		for key, value := range schema.OptionalProperties {
 			path.Push(NamePathSegment{Property: key})
-			name, err := e.walk(path, value)
+			property, err := e.walk(path, value)
 			if err != nil {
-				return "", err
+				return walkResult{}, err
 			}
 
-			optional[key] = name
+			optionalTypes[key] = property.TypeName
+			optionalValidators[key] = property.ValidatorName
+			propertyNames[key] = metadataPropertyName(schema, key)
 			path.Pop()
 		}
 
-		return e.Emitter.EmitStruct(e.Out, Struct{
-			Path:               path,
-			RequiredProperties: required,
-			OptionalProperties: optional,
-		})
+		result := walkResult{}
+
+		if e.wantTypes() {
+			structName, err := e.Emitter.EmitStruct(e.FS, Struct{
+				Path:               path,
+				Name:               metadataTypeName(schema),
+				Description:        metadataDescription(schema),
+				RequiredProperties: requiredTypes,
+				OptionalProperties: optionalTypes,
+				PropertyNames:      propertyNames,
+			})
+			if err != nil {
+				return walkResult{}, err
+			}
+
+			if err := e.claimName(structName); err != nil {
+				return walkResult{}, err
+			}
+
+			result.TypeName = structName
+		}
+
+		if e.wantValidators() {
+			validatorName, err := e.Emitter.(ValidatorEmitter).EmitStructValidator(e.FS, Struct{
+				Path:               path,
+				Name:               metadataTypeName(schema),
+				Description:        metadataDescription(schema),
+				RequiredProperties: requiredValidators,
+				OptionalProperties: optionalValidators,
+				PropertyNames:      propertyNames,
+			})
+			if err != nil {
+				return walkResult{}, err
+			}
+
+			result.ValidatorName = validatorName
+		}
+
+		return result, nil
 	case jsonvalidate.SchemaKindValues:
 		path.Push(NamePathSegment{Values: true})
-		name, err := e.walk(path, schema.Values)
+		values, err := e.walk(path, schema.Values)
 		if err != nil {
-			return "", err
+			return walkResult{}, err
 		}
 
 		path.Pop()
 
-		return e.Emitter.EmitValues(e.Out, Values{
-			Path:   path,
-			Values: name,
-		})
+		result := walkResult{}
+
+		if e.wantTypes() {
+			valuesName, err := e.Emitter.EmitValues(e.FS, Values{
+				Path:        path,
+				Name:        metadataTypeName(schema),
+				Description: metadataDescription(schema),
+				Values:      values.TypeName,
+			})
+			if err != nil {
+				return walkResult{}, err
+			}
+
+			if err := e.claimName(valuesName); err != nil {
+				return walkResult{}, err
+			}
+
+			result.TypeName = valuesName
+		}
+
+		if e.wantValidators() {
+			validatorName, err := e.Emitter.(ValidatorEmitter).EmitValuesValidator(e.FS, Values{
+				Path:        path,
+				Name:        metadataTypeName(schema),
+				Description: metadataDescription(schema),
+				Values:      values.ValidatorName,
+			})
+			if err != nil {
+				return walkResult{}, err
+			}
+
+			result.ValidatorName = validatorName
+		}
+
+		return result, nil
 	case jsonvalidate.SchemaKindDiscriminator:
 		path.Push(NamePathSegment{Variants: true})
-		variants := []string{}
+		variantTypeNames := []string{}
+		variantValidatorNames := []string{}
+		variantTypeNamesByTag := map[string]string{}
+		variantValidatorNamesByTag := map[string]string{}
 		for key, variant := range schema.DiscriminatorMapping {
 			// Check that the variant value is of kind "properties", as this is the
 			// only format supported (at least for now).
 			if variant.Kind != jsonvalidate.SchemaKindProperties {
-				return "", errors.New("schemas within `mapping` must use only properties and optionalProperties")
+				return walkResult{}, errors.New("schemas within `mapping` must use only properties and optionalProperties")
 			}
 
 			path.Push(NamePathSegment{Property: key})
 
-			required := map[string]string{}
-			for key, value := range schema.Properties {
+			requiredTypes := map[string]string{}
+			requiredValidators := map[string]string{}
+			propertyNames := map[string]string{}
+			for key, value := range variant.Properties {
 				path.Push(NamePathSegment{Property: key})
-				name, err := e.walk(path, value)
+				property, err := e.walk(path, value)
 				if err != nil {
-					return "", err
+					return walkResult{}, err
 				}
 
-				required[key] = name
+				requiredTypes[key] = property.TypeName
+				requiredValidators[key] = property.ValidatorName
+				propertyNames[key] = metadataPropertyName(variant, key)
 				path.Pop()
 			}
 
-			optional := map[string]string{}
-			for key, value := range schema.OptionalProperties {
+			optionalTypes := map[string]string{}
+			optionalValidators := map[string]string{}
+			for key, value := range variant.OptionalProperties {
 				path.Push(NamePathSegment{Property: key})
-				name, err := e.walk(path, value)
+				property, err := e.walk(path, value)
 				if err != nil {
-					return "", err
+					return walkResult{}, err
 				}
 
-				optional[key] = name
+				optionalTypes[key] = property.TypeName
+				optionalValidators[key] = property.ValidatorName
+				propertyNames[key] = metadataPropertyName(variant, key)
 				path.Pop()
 			}
 
-			name, err := e.Emitter.EmitVariant(e.Out, Variant{
-				Path:               path,
-				TagName:            schema.DiscriminatorPropertyName,
-				TagValue:           key,
-				RequiredProperties: required,
-				OptionalProperties: optional,
-			})
+			if e.wantTypes() {
+				variantName, err := e.Emitter.EmitVariant(e.FS, Variant{
+					Path:               path,
+					Name:               metadataTypeName(variant),
+					Description:        metadataDescription(variant),
+					TagName:            schema.DiscriminatorPropertyName,
+					TagValue:           key,
+					RequiredProperties: requiredTypes,
+					OptionalProperties: optionalTypes,
+					PropertyNames:      propertyNames,
+				})
+				if err != nil {
+					return walkResult{}, err
+				}
 
-			if err != nil {
-				return "", err
+				if err := e.claimName(variantName); err != nil {
+					return walkResult{}, err
+				}
+
+				variantTypeNames = append(variantTypeNames, variantName)
+				variantTypeNamesByTag[key] = variantName
 			}
 
-			path.Pop()
+			if e.wantValidators() {
+				validatorName, err := e.Emitter.(ValidatorEmitter).EmitVariantValidator(e.FS, Variant{
+					Path:               path,
+					Name:               metadataTypeName(variant),
+					Description:        metadataDescription(variant),
+					TagName:            schema.DiscriminatorPropertyName,
+					TagValue:           key,
+					RequiredProperties: requiredValidators,
+					OptionalProperties: optionalValidators,
+					PropertyNames:      propertyNames,
+				})
+				if err != nil {
+					return walkResult{}, err
+				}
 
-			variants = append(variants, name)
+				variantValidatorNames = append(variantValidatorNames, validatorName)
+				variantValidatorNamesByTag[key] = validatorName
+			}
+
+			path.Pop()
 		}
 
 		path.Pop()
 
-		return e.Emitter.EmitUnion(e.Out, Union{
-			Path:     path,
-			Variants: variants,
-		})
+		union := Union{
+			Path:          path,
+			Name:          metadataTypeName(schema),
+			Description:   metadataDescription(schema),
+			TagName:       schema.DiscriminatorPropertyName,
+			Variants:      variantTypeNames,
+			VariantsByTag: variantTypeNamesByTag,
+		}
+
+		result := walkResult{}
+
+		if e.wantTypes() {
+			unionName, err := e.Emitter.EmitUnion(e.FS, union)
+			if err != nil {
+				return walkResult{}, err
+			}
+
+			if err := e.claimName(unionName); err != nil {
+				return walkResult{}, err
+			}
+
+			result.TypeName = unionName
+		}
+
+		if e.wantValidators() {
+			union.Variants = variantValidatorNames
+			union.VariantsByTag = variantValidatorNamesByTag
+
+			validatorName, err := e.Emitter.(ValidatorEmitter).EmitUnionValidator(e.FS, union)
+			if err != nil {
+				return walkResult{}, err
+			}
+
+			result.ValidatorName = validatorName
+		}
+
+		return result, nil
+	}
+
+	return walkResult{}, nil
+}
+
+// walkPrimitiveFormatted handles a SchemaKindType schema, substituting a
+// more specific type for plain (the Emitter's plain primitive rendering) if
+// `metadata.format` is set, before delegating to walkPrimitive for
+// validator handling, which always operates on the underlying kind
+// regardless of format. `metadata.format` only applies to strings; it's
+// ignored on any other kind, since none of the built-in formats (or the
+// FormatEmitter extension point) have a meaningful representation for a
+// null, boolean, or number.
+func (e *Encoder) walkPrimitiveFormatted(schema *jsonvalidate.Schema, kind PrimitiveKind, plain string) (walkResult, error) {
+	format := metadataFormat(schema)
+	if format == "" || kind != PrimitiveKindString || !e.wantTypes() {
+		return e.walkPrimitive(kind, plain)
+	}
+
+	typeName, err := e.formatTypeName(kind, format)
+	if err != nil {
+		return walkResult{}, err
+	}
+
+	return e.walkPrimitive(kind, typeName)
+}
+
+// formatTypeName returns the type name to use for format, preferring a
+// handler registered via RegisterFormat, then falling back to the Emitter's
+// own FormatEmitter implementation. The result is memoized so that a format
+// used at multiple sites is only emitted once.
+func (e *Encoder) formatTypeName(kind PrimitiveKind, format string) (string, error) {
+	key := formatKey{Kind: kind, Format: format}
+	if name, ok := e.formatNames[key]; ok {
+		return name, nil
+	}
+
+	name, err := e.emitFormat(kind, format)
+	if err != nil {
+		return "", err
+	}
+
+	if err := e.claimName(name); err != nil {
+		return "", err
+	}
+
+	if e.formatNames == nil {
+		e.formatNames = map[formatKey]string{}
+	}
+
+	e.formatNames[key] = name
+	return name, nil
+}
+
+func (e *Encoder) emitFormat(kind PrimitiveKind, format string) (string, error) {
+	if handler, ok := e.formats[format]; ok {
+		return handler(e.FS, format)
+	}
+
+	fe, ok := e.Emitter.(FormatEmitter)
+	if !ok {
+		return "", fmt.Errorf("json-validate-codegen: no format handler registered for %#v, and %T does not implement FormatEmitter", format, e.Emitter)
+	}
+
+	return fe.EmitFormattedPrimitive(e.FS, kind, format)
+}
+
+// walkPrimitive handles the leaf case shared by every primitive schema kind:
+// the type name is always already computed by the caller, and the validator
+// (if requested) comes from EmitPrimitiveValidator rather than a writer-based
+// Emit method, since primitives don't get their own declaration.
+func (e *Encoder) walkPrimitive(kind PrimitiveKind, typeName string) (walkResult, error) {
+	result := walkResult{TypeName: typeName}
+
+	if e.wantValidators() {
+		validatorName, err := e.Emitter.(ValidatorEmitter).EmitPrimitiveValidator(kind)
+		if err != nil {
+			return walkResult{}, err
+		}
+
+		result.ValidatorName = validatorName
 	}
 
-	return "", nil
+	return result, nil
 }
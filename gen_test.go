@@ -0,0 +1,213 @@
+package codegen_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/json-validate/json-validate-codegen"
+	"github.com/json-validate/json-validate-codegen/golang"
+	"github.com/json-validate/json-validate-codegen/typescript"
+	"github.com/json-validate/json-validate-go"
+)
+
+// run executes Encoder.Run with emitter against a registry containing just
+// root, returning everything written to the single combined output stream.
+func run(t *testing.T, emitter codegen.Emitter, emit []codegen.EmitKind, root *jsonvalidate.Schema) string {
+	t.Helper()
+
+	var out bytes.Buffer
+	encoder := codegen.Encoder{
+		FS:       codegen.SingleWriterFS{Out: &out},
+		Emit:     emit,
+		Registry: jsonvalidate.Registry{Schemas: []*jsonvalidate.Schema{root}},
+		Emitter:  emitter,
+	}
+
+	if err := encoder.Run(); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	return out.String()
+}
+
+func TestNullableProperty(t *testing.T) {
+	root := &jsonvalidate.Schema{
+		Kind: jsonvalidate.SchemaKindProperties,
+		Properties: map[string]*jsonvalidate.Schema{
+			"foo": {
+				Kind:     jsonvalidate.SchemaKindType,
+				Type:     jsonvalidate.SchemaTypeString,
+				Nullable: true,
+			},
+		},
+	}
+
+	out := run(t, &typescript.Emitter{}, nil, root)
+
+	if !strings.Contains(out, "foo: string | null;") {
+		t.Errorf("expected output to contain %q, got:\n%s", "foo: string | null;", out)
+	}
+}
+
+func TestNullablePropertyGo(t *testing.T) {
+	root := &jsonvalidate.Schema{
+		Kind: jsonvalidate.SchemaKindProperties,
+		Properties: map[string]*jsonvalidate.Schema{
+			"Foo": {
+				Kind:     jsonvalidate.SchemaKindType,
+				Type:     jsonvalidate.SchemaTypeString,
+				Nullable: true,
+			},
+		},
+	}
+
+	out := run(t, &golang.Emitter{}, nil, root)
+
+	if !strings.Contains(out, "*string") {
+		t.Errorf("expected output to contain a pointer type for a nullable field, got:\n%s", out)
+	}
+}
+
+func TestOptionalNullablePropertyGoIsSinglePointer(t *testing.T) {
+	root := &jsonvalidate.Schema{
+		Kind: jsonvalidate.SchemaKindProperties,
+		OptionalProperties: map[string]*jsonvalidate.Schema{
+			"Foo": {
+				Kind:     jsonvalidate.SchemaKindType,
+				Type:     jsonvalidate.SchemaTypeString,
+				Nullable: true,
+			},
+		},
+	}
+
+	out := run(t, &golang.Emitter{}, nil, root)
+
+	if strings.Contains(out, "**string") {
+		t.Errorf("expected a field that's both optional and nullable to stay a single pointer, got:\n%s", out)
+	}
+
+	if !strings.Contains(out, "*string") {
+		t.Errorf("expected output to contain a pointer type for an optional nullable field, got:\n%s", out)
+	}
+}
+
+func TestSharedRefEmittedOnce(t *testing.T) {
+	def := &jsonvalidate.Schema{
+		Kind: jsonvalidate.SchemaKindProperties,
+		Metadata: map[string]interface{}{
+			"typeName": "Shared",
+		},
+		Properties: map[string]*jsonvalidate.Schema{
+			"id": {Kind: jsonvalidate.SchemaKindType, Type: jsonvalidate.SchemaTypeString},
+		},
+	}
+
+	first := &jsonvalidate.Schema{
+		Definitions: map[string]*jsonvalidate.Schema{"shared": def},
+		Kind:        jsonvalidate.SchemaKindRef,
+		Ref:         "shared",
+	}
+
+	second := &jsonvalidate.Schema{
+		Kind: jsonvalidate.SchemaKindRef,
+		Ref:  "shared",
+	}
+
+	var out bytes.Buffer
+	encoder := codegen.Encoder{
+		FS:       codegen.SingleWriterFS{Out: &out},
+		Registry: jsonvalidate.Registry{Schemas: []*jsonvalidate.Schema{first, second}},
+		Emitter:  &typescript.Emitter{},
+	}
+
+	if err := encoder.Run(); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	if n := strings.Count(out.String(), "export interface Shared"); n != 1 {
+		t.Errorf("expected the shared definition to be emitted exactly once, got %d occurrences:\n%s", n, out.String())
+	}
+}
+
+func TestBuiltinFormat(t *testing.T) {
+	root := &jsonvalidate.Schema{
+		Kind: jsonvalidate.SchemaKindProperties,
+		Properties: map[string]*jsonvalidate.Schema{
+			"createdAt": {
+				Kind:     jsonvalidate.SchemaKindType,
+				Type:     jsonvalidate.SchemaTypeString,
+				Metadata: map[string]interface{}{"format": "date-time"},
+			},
+		},
+	}
+
+	out := run(t, &typescript.Emitter{}, nil, root)
+
+	if !strings.Contains(out, "createdAt: DateTime;") {
+		t.Errorf("expected output to reference the branded DateTime type, got:\n%s", out)
+	}
+
+	if !strings.Contains(out, `__brand: "date-time"`) {
+		t.Errorf("expected output to declare the DateTime branded type, got:\n%s", out)
+	}
+}
+
+func TestOptionalPropertyValidatorSkipsUndefined(t *testing.T) {
+	root := &jsonvalidate.Schema{
+		Kind: jsonvalidate.SchemaKindProperties,
+		OptionalProperties: map[string]*jsonvalidate.Schema{
+			"foo": {Kind: jsonvalidate.SchemaKindType, Type: jsonvalidate.SchemaTypeString},
+		},
+	}
+
+	out := run(t, &typescript.Emitter{}, []codegen.EmitKind{codegen.EmitKindValidators}, root)
+
+	if !strings.Contains(out, `if (obj["foo"] !== undefined)`) {
+		t.Errorf("expected the optional property validator to be guarded by an undefined check, got:\n%s", out)
+	}
+}
+
+func TestDirFSEmitsImports(t *testing.T) {
+	root := &jsonvalidate.Schema{
+		Kind: jsonvalidate.SchemaKindProperties,
+		Properties: map[string]*jsonvalidate.Schema{
+			"bar": {
+				Kind: jsonvalidate.SchemaKindProperties,
+				Metadata: map[string]interface{}{
+					"typeName": "Bar",
+				},
+				Properties: map[string]*jsonvalidate.Schema{
+					"id": {Kind: jsonvalidate.SchemaKindType, Type: jsonvalidate.SchemaTypeString},
+				},
+			},
+		},
+	}
+
+	dir := t.TempDir()
+	encoder := codegen.Encoder{
+		FS:       &codegen.DirFS{Dir: dir},
+		Emit:     []codegen.EmitKind{codegen.EmitKindTypes, codegen.EmitKindValidators},
+		Registry: jsonvalidate.Registry{Schemas: []*jsonvalidate.Schema{root}},
+		Emitter:  &typescript.Emitter{},
+	}
+
+	if err := encoder.Run(); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	out, err := os.ReadFile(filepath.Join(dir, "Default.ts"))
+	if err != nil {
+		t.Fatalf("failed to read Default.ts: %v", err)
+	}
+
+	if !strings.Contains(string(out), `import { Bar } from "./Bar";`) {
+		t.Errorf("expected Default.ts to import the Bar type, got:\n%s", out)
+	}
+
+	if !strings.Contains(string(out), `import { validateBar } from "./Bar";`) {
+		t.Errorf("expected Default.ts to import validateBar, got:\n%s", out)
+	}
+}
@@ -0,0 +1,408 @@
+// Package golang implements a codegen.Emitter that produces idiomatic Go
+// types from JSON Validate schemas.
+package golang
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/json-validate/json-validate-codegen"
+)
+
+type headerArgs struct {
+	PackageName string
+}
+
+type arrayArgs struct {
+	Name        string
+	Description string
+	Elements    string
+}
+
+type structArgs struct {
+	Name        string
+	Description string
+	Properties  []structArgsProperties
+}
+
+type structArgsProperties struct {
+	Name     string
+	Type     string
+	JSONName string
+	Optional bool
+}
+
+type valuesArgs struct {
+	Name        string
+	Description string
+	Values      string
+}
+
+type variantArgs struct {
+	Name        string
+	Description string
+	TagName     string
+	TagJSONName string
+	TagValue    string
+	Properties  []structArgsProperties
+}
+
+type unionArgs struct {
+	Name        string
+	Description string
+	Variants    []string
+}
+
+type formatArgs struct {
+	Name   string
+	Format string
+}
+
+var (
+	headerFmt = template.Must(template.New("header").Parse(`
+{{- /* */ -}}
+package {{ .PackageName }}
+`))
+
+	arrayFmt = template.Must(template.New("array").Parse(`
+{{- /* */ -}}
+{{ if .Description }}// {{ .Name }} {{ .Description }}
+{{ end -}}
+type {{ .Name }} []{{ .Elements }}
+`))
+
+	structFmt = template.Must(template.New("struct").Parse(`
+{{- /* */ -}}
+{{ if .Description }}// {{ .Name }} {{ .Description }}
+{{ end -}}
+type {{ .Name }} struct {
+{{- range .Properties }}
+	{{ .Name }} {{ .Type }} ` + "`" + `json:"{{ .JSONName }}{{ if .Optional }},omitempty{{ end }}"` + "`" + `
+{{- end }}
+}
+`))
+
+	valuesFmt = template.Must(template.New("values").Parse(`
+{{- /* */ -}}
+{{ if .Description }}// {{ .Name }} {{ .Description }}
+{{ end -}}
+type {{ .Name }} map[string]{{ .Values }}
+`))
+
+	variantFmt = template.Must(template.New("variant").Parse(`
+{{- /* */ -}}
+{{ if .Description }}// {{ .Name }} {{ .Description }}
+{{ end -}}
+type {{ .Name }} struct {
+	{{ .TagName }} string ` + "`" + `json:"{{ .TagJSONName }}"` + "`" + `
+{{- range .Properties }}
+	{{ .Name }} {{ .Type }} ` + "`" + `json:"{{ .JSONName }}{{ if .Optional }},omitempty{{ end }}"` + "`" + `
+{{- end }}
+}
+`))
+
+	unionFmt = template.Must(template.New("union").Parse(`
+{{- /* */ -}}
+{{ if .Description }}// {{ $.Name }} {{ .Description }}
+{{ end -}}
+type {{ $.Name }} interface {
+	is{{ $.Name }}()
+}
+{{ range .Variants }}
+func ({{ . }}) is{{ $.Name }}() {}
+{{ end -}}
+`))
+
+	formatFmt = template.Must(template.New("format").Parse(`
+{{- /* */ -}}
+// {{ .Name }} is an opaque string type for schemas with ` + "`metadata.format`" + ` set to "{{ .Format }}".
+type {{ .Name }} string
+`))
+)
+
+// builtinFormats maps the `metadata.format` values this Emitter knows how to
+// render as an opaque type without any caller configuration, to the Go type
+// name it emits for each.
+var builtinFormats = map[string]string{
+	"date-time": "DateTime",
+	"duration":  "Duration",
+	"uuid":      "UUID",
+	"uri":       "URI",
+}
+
+// Emitter is an Emitter that outputs Go code.
+type Emitter struct {
+	packageName string
+
+	// header tracks whether the package clause has already been written to
+	// a given file, so that it's written once per file under a FileSystem
+	// that hands out one real file per type (e.g. DirFS), or once overall
+	// when everything is concatenated onto a single writer (e.g.
+	// SingleWriterFS).
+	header codegen.FilePreamble
+}
+
+func (e *Emitter) EmitHeader(fs codegen.FileSystem, packageName string) error {
+	if packageName == "" {
+		packageName = "main"
+	}
+
+	e.packageName = packageName
+	return nil
+}
+
+// openFile opens the file named file, writing the package clause at its top
+// if this is the first time file has been seen.
+func (e *Emitter) openFile(fs codegen.FileSystem, file string) (io.WriteCloser, error) {
+	out, err := fs.OpenFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.header.Ensure(fs, file, func() error {
+		return headerFmt.Execute(out, headerArgs{PackageName: e.packageName})
+	}); err != nil {
+		out.Close()
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (e *Emitter) PrimitiveEmpty() string {
+	return "interface{}"
+}
+
+func (e *Emitter) PrimitiveNull() string {
+	return "interface{}"
+}
+
+func (e *Emitter) PrimitiveBoolean() string {
+	return "bool"
+}
+
+func (e *Emitter) PrimitiveNumber() string {
+	return "float64"
+}
+
+func (e *Emitter) PrimitiveString() string {
+	return "string"
+}
+
+func (e *Emitter) WrapNullable(inner string) string {
+	return "*" + inner
+}
+
+func name(path *codegen.NamePath) string {
+	name := "Default"
+	for _, s := range path.Segments {
+		if s.Elements {
+			name = name + "Element"
+		} else if s.Variants {
+			name = name + "Variant"
+		} else if s.Values {
+			name = name + "Value"
+		} else {
+			name = name + s.Property
+		}
+	}
+
+	return name
+}
+
+// typeName returns suggested if it's non-empty, falling back to a name
+// synthesised from path otherwise.
+func typeName(path *codegen.NamePath, suggested string) string {
+	if suggested != "" {
+		return suggested
+	}
+
+	return name(path)
+}
+
+// fileName returns the name of the file the type at path (or named
+// suggested) should be written to.
+func fileName(path *codegen.NamePath, suggested string) string {
+	return typeName(path, suggested) + ".go"
+}
+
+// propertyName returns the identifier to use for a property named key,
+// honoring a `metadata.propertyNames` override if one was given.
+func propertyName(propertyNames map[string]string, key string) string {
+	if override, ok := propertyNames[key]; ok && override != "" {
+		return exportedName(override)
+	}
+
+	return exportedName(key)
+}
+
+// optionalType returns the Go type to use for an optional struct field whose
+// value (when present) is of type value, pointer-wrapping it to distinguish
+// absence from the zero value. value is already a pointer (e.g. "*string")
+// when its schema is also `nullable: true`, in which case the existing
+// pointer already distinguishes absence from null and wrapping it again
+// would produce "**string".
+func optionalType(value string) string {
+	if strings.HasPrefix(value, "*") {
+		return value
+	}
+
+	return "*" + value
+}
+
+func (e *Emitter) EmitArray(fs codegen.FileSystem, array codegen.Array) (string, error) {
+	out, err := e.openFile(fs, fileName(array.Path, array.Name))
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	args := arrayArgs{
+		Name:        typeName(array.Path, array.Name),
+		Description: array.Description,
+		Elements:    array.Elements,
+	}
+
+	err = arrayFmt.Execute(out, args)
+	return args.Name, err
+}
+
+func (e *Emitter) EmitStruct(fs codegen.FileSystem, strukt codegen.Struct) (string, error) {
+	out, err := e.openFile(fs, fileName(strukt.Path, strukt.Name))
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	args := structArgs{
+		Name:        typeName(strukt.Path, strukt.Name),
+		Description: strukt.Description,
+		Properties:  []structArgsProperties{},
+	}
+
+	for key, value := range strukt.RequiredProperties {
+		args.Properties = append(args.Properties, structArgsProperties{
+			Name:     propertyName(strukt.PropertyNames, key),
+			Type:     value,
+			JSONName: key,
+		})
+	}
+
+	for key, value := range strukt.OptionalProperties {
+		args.Properties = append(args.Properties, structArgsProperties{
+			Name:     propertyName(strukt.PropertyNames, key),
+			Type:     optionalType(value),
+			JSONName: key,
+			Optional: true,
+		})
+	}
+
+	err = structFmt.Execute(out, args)
+	return args.Name, err
+}
+
+func (e *Emitter) EmitValues(fs codegen.FileSystem, values codegen.Values) (string, error) {
+	out, err := e.openFile(fs, fileName(values.Path, values.Name))
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	args := valuesArgs{
+		Name:        typeName(values.Path, values.Name),
+		Description: values.Description,
+		Values:      values.Values,
+	}
+
+	err = valuesFmt.Execute(out, args)
+	return args.Name, err
+}
+
+func (e *Emitter) EmitVariant(fs codegen.FileSystem, variant codegen.Variant) (string, error) {
+	out, err := e.openFile(fs, fileName(variant.Path, variant.Name))
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	args := variantArgs{
+		Name:        typeName(variant.Path, variant.Name),
+		Description: variant.Description,
+		TagName:     exportedName(variant.TagName),
+		TagJSONName: variant.TagName,
+		TagValue:    variant.TagValue,
+	}
+
+	for key, value := range variant.RequiredProperties {
+		args.Properties = append(args.Properties, structArgsProperties{
+			Name:     propertyName(variant.PropertyNames, key),
+			Type:     value,
+			JSONName: key,
+		})
+	}
+
+	for key, value := range variant.OptionalProperties {
+		args.Properties = append(args.Properties, structArgsProperties{
+			Name:     propertyName(variant.PropertyNames, key),
+			Type:     optionalType(value),
+			JSONName: key,
+			Optional: true,
+		})
+	}
+
+	err = variantFmt.Execute(out, args)
+	return args.Name, err
+}
+
+func (e *Emitter) EmitUnion(fs codegen.FileSystem, union codegen.Union) (string, error) {
+	out, err := e.openFile(fs, fileName(union.Path, union.Name))
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	args := unionArgs{
+		Name:        typeName(union.Path, union.Name),
+		Description: union.Description,
+		Variants:    union.Variants,
+	}
+
+	err = unionFmt.Execute(out, args)
+	return args.Name, err
+}
+
+// EmitFormattedPrimitive implements codegen.FormatEmitter, rendering the
+// built-in formats as an opaque string type. Callers wanting a different
+// representation (e.g. time.Time for "date-time") should register their own
+// handler via Encoder.RegisterFormat instead.
+func (e *Emitter) EmitFormattedPrimitive(fs codegen.FileSystem, base codegen.PrimitiveKind, format string) (string, error) {
+	name, ok := builtinFormats[format]
+	if !ok {
+		return "", fmt.Errorf("golang: no built-in handler for format %#v; register one with Encoder.RegisterFormat", format)
+	}
+
+	out, err := e.openFile(fs, name+".go")
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	args := formatArgs{Name: name, Format: format}
+	err = formatFmt.Execute(out, args)
+	return args.Name, err
+}
+
+// exportedName capitalizes the first letter of a JSON property name so that
+// it becomes a valid, exported Go struct field name.
+func exportedName(s string) string {
+	if s == "" {
+		return s
+	}
+
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
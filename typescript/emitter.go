@@ -4,18 +4,22 @@ import (
 	"fmt"
 	"html/template"
 	"io"
+	"regexp"
+	"strings"
 
 	"github.com/json-validate/json-validate-codegen"
 )
 
 type arrayArgs struct {
-	Name     string
-	Elements string
+	Name        string
+	Description string
+	Elements    string
 }
 
 type structArgs struct {
-	Name       string
-	Properties []structArgsProperties
+	Name        string
+	Description string
+	Properties  []structArgsProperties
 }
 
 type structArgsProperties struct {
@@ -24,30 +28,42 @@ type structArgsProperties struct {
 }
 
 type valueArgs struct {
-	Name   string
-	Values string
+	Name        string
+	Description string
+	Values      string
 }
 
 type variantArgs struct {
-	Name       string
-	TagName    string
-	TagValue   string
-	Properties []structArgsProperties
+	Name        string
+	Description string
+	TagName     string
+	TagValue    string
+	Properties  []structArgsProperties
 }
 
 type unionArgs struct {
-	Name     string
-	Variants []string
+	Name        string
+	Description string
+	Variants    []string
+}
+
+type formatArgs struct {
+	Name   string
+	Format string
 }
 
 var (
 	arrayFmt = template.Must(template.New("array").Parse(`
 {{- /* */ -}}
+{{ if .Description }}/** {{ .Description }} */
+{{ end -}}
 export type {{ .Name }} = {{ .Elements }}[];
 `))
 
 	structFmt = template.Must(template.New("struct").Parse(`
 {{- /* */ -}}
+{{ if .Description }}/** {{ .Description }} */
+{{ end -}}
 export interface {{ .Name }} {
 {{- range .Properties }}
   {{ .Name }}: {{ .Type }};
@@ -57,6 +73,8 @@ export interface {{ .Name }} {
 
 	valuesFmt = template.Must(template.New("values").Parse(`
 {{- /* */ -}}
+{{ if .Description }}/** {{ .Description }} */
+{{ end -}}
 export interface {{ .Name }} {
 	[key: string]: {{ .Values }};
 }
@@ -64,6 +82,8 @@ export interface {{ .Name }} {
 
 	variantFmt = template.Must(template.New("variant").Parse(`
 {{- /* */ -}}
+{{ if .Description }}/** {{ .Description }} */
+{{ end -}}
 export interface {{ .Name }} {
 	{{ .TagName }}: "{{ .TagValue }}";
 {{- range .Properties }}
@@ -74,15 +94,44 @@ export interface {{ .Name }} {
 
 	unionFmt = template.Must(template.New("union").Parse(`
 {{- /* */ -}}
+{{ if .Description }}/** {{ .Description }} */
+{{ end -}}
 export type {{ .Name }} =
 {{- range $index, $variant := .Variants }}
 	{{ if $index }}|{{ end }} {{ $variant }}
 {{- end }}
+`))
+
+	formatFmt = template.Must(template.New("format").Parse(`
+{{- /* */ -}}
+/** A branded string type for schemas with ` + "`metadata.format`" + ` set to "{{ .Format }}". */
+export type {{ .Name }} = string & { readonly __brand: "{{ .Format }}" };
 `))
 )
 
+// builtinFormats maps the `metadata.format` values this Emitter knows how to
+// render as a branded type without any caller configuration, to the
+// TypeScript type name it emits for each.
+var builtinFormats = map[string]string{
+	"date-time": "DateTime",
+	"duration":  "Duration",
+	"uuid":      "UUID",
+	"uri":       "URI",
+}
+
 // Emitter is an Emitter that outputs TypeScript code.
-type Emitter struct{}
+type Emitter struct {
+	// preamble tracks whether validatorPreamble has already been written to
+	// a given file, so that it's only written once per file even though
+	// several EmitXValidator calls may write to the same file.
+	preamble codegen.FilePreamble
+
+	// imports tracks, per file, which names have already been given an
+	// import statement, so that a file referencing the same type or
+	// validator more than once (e.g. a struct and its validator both
+	// referencing "Foo") doesn't emit a duplicate import.
+	imports map[string]map[string]bool
+}
 
 func (e *Emitter) PrimitiveEmpty() string {
 	return "any"
@@ -104,9 +153,15 @@ func (e *Emitter) PrimitiveString() string {
 	return "string"
 }
 
-func (e *Emitter) EmitArray(out io.Writer, array codegen.Array) (string, error) {
+func (e *Emitter) WrapNullable(inner string) string {
+	return inner + " | null"
+}
+
+// name returns the name this emitter should use for the type at path,
+// synthesising one from the path's segments.
+func name(path *codegen.NamePath) string {
 	name := "Default"
-	for _, s := range array.Path.Segments {
+	for _, s := range path.Segments {
 		if s.Elements {
 			name = name + "Element"
 		} else if s.Variants {
@@ -118,39 +173,151 @@ func (e *Emitter) EmitArray(out io.Writer, array codegen.Array) (string, error)
 		}
 	}
 
+	return name
+}
+
+// typeName returns suggested if it's non-empty, falling back to a name
+// synthesised from path otherwise.
+func typeName(path *codegen.NamePath, suggested string) string {
+	if suggested != "" {
+		return suggested
+	}
+
+	return name(path)
+}
+
+// fileName returns the name of the file the type at path (or named
+// suggested) should be written to.
+func fileName(path *codegen.NamePath, suggested string) string {
+	return typeName(path, suggested) + ".ts"
+}
+
+// primitiveTypeNames holds every type expression PrimitiveX can return,
+// i.e. one that's always available in every file and so never needs an
+// import.
+var primitiveTypeNames = map[string]bool{
+	"any":     true,
+	"null":    true,
+	"boolean": true,
+	"number":  true,
+	"string":  true,
+}
+
+// referencedTypeName extracts the declared type name referenced by a type
+// expression such as "Foo" or the nullable-wrapped "Foo | null", reporting
+// ok == false if expr is a primitive that doesn't need importing.
+func referencedTypeName(expr string) (string, bool) {
+	name := strings.TrimSuffix(expr, " | null")
+	if primitiveTypeNames[name] {
+		return "", false
+	}
+
+	return name, true
+}
+
+// validatorRefPattern matches a reference to another emitted validator
+// function (e.g. "validateFoo") embedded in a validator expression, which
+// may itself be wrapped in a helper call like __jvcValidateNullable(...).
+// It doesn't match the preamble's own __jvcValidate* helpers, since those
+// capitalize the "V".
+var validatorRefPattern = regexp.MustCompile(`\bvalidate[A-Z][A-Za-z0-9_]*`)
+
+// emitImports writes an import line for every name in refs, skipping self
+// and anything already imported into file. A validator name (one matching
+// validatorRefPattern) is imported from the file of the type it validates,
+// e.g. `import { validateFoo } from "./Foo";`; any other name is imported
+// from the file of the same name, e.g. `import { Foo } from "./Foo";`. This
+// is so that
+// a type or validator referencing a declaration from another generated
+// file under a MultiFile FileSystem (e.g. DirFS) compiles. SingleWriterFS
+// concatenates every file onto one shared stream, so nothing needs
+// importing there.
+func (e *Emitter) emitImports(fs codegen.FileSystem, out io.Writer, file, self string, refs []string) error {
+	mf, ok := fs.(codegen.MultiFile)
+	if !ok || !mf.IsMultiFile() {
+		return nil
+	}
+
+	if e.imports == nil {
+		e.imports = map[string]map[string]bool{}
+	}
+	if e.imports[file] == nil {
+		e.imports[file] = map[string]bool{}
+	}
+
+	for _, ref := range refs {
+		if ref == self || e.imports[file][ref] {
+			continue
+		}
+		e.imports[file][ref] = true
+
+		module := ref
+		if validatorRefPattern.MatchString(ref) {
+			module = strings.TrimPrefix(ref, "validate")
+		}
+
+		if _, err := fmt.Fprintf(out, "import { %s } from \"./%s\";\n", ref, module); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *Emitter) EmitArray(fs codegen.FileSystem, array codegen.Array) (string, error) {
+	name := typeName(array.Path, array.Name)
+	file := fileName(array.Path, array.Name)
+
+	out, err := fs.OpenFile(file)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	var refs []string
+	if ref, ok := referencedTypeName(array.Elements); ok {
+		refs = append(refs, ref)
+	}
+	if err := e.emitImports(fs, out, file, name, refs); err != nil {
+		return "", err
+	}
+
 	args := arrayArgs{
-		Name:     name,
-		Elements: array.Elements,
+		Name:        name,
+		Description: array.Description,
+		Elements:    array.Elements,
 	}
 
-	err := arrayFmt.Execute(out, args)
+	err = arrayFmt.Execute(out, args)
 	return name, err
 }
 
-func (e *Emitter) EmitStruct(out io.Writer, strukt codegen.Struct) (string, error) {
-	name := "Default"
-	for _, s := range strukt.Path.Segments {
-		if s.Elements {
-			name = name + "Element"
-		} else if s.Variants {
-			name = name + "Variant"
-		} else if s.Values {
-			name = name + "Value"
-		} else {
-			name = name + s.Property
-		}
+func (e *Emitter) EmitStruct(fs codegen.FileSystem, strukt codegen.Struct) (string, error) {
+	name := typeName(strukt.Path, strukt.Name)
+	file := fileName(strukt.Path, strukt.Name)
+
+	out, err := fs.OpenFile(file)
+	if err != nil {
+		return "", err
 	}
+	defer out.Close()
 
 	args := structArgs{
-		Name:       name,
-		Properties: []structArgsProperties{},
+		Name:        name,
+		Description: strukt.Description,
+		Properties:  []structArgsProperties{},
 	}
 
+	var refs []string
+
 	for key, value := range strukt.RequiredProperties {
 		args.Properties = append(args.Properties, structArgsProperties{
 			Name: key,
 			Type: value,
 		})
+		if ref, ok := referencedTypeName(value); ok {
+			refs = append(refs, ref)
+		}
 	}
 
 	for key, value := range strukt.OptionalProperties {
@@ -158,61 +325,75 @@ func (e *Emitter) EmitStruct(out io.Writer, strukt codegen.Struct) (string, erro
 			Name: fmt.Sprintf("%s?", key),
 			Type: value,
 		})
+		if ref, ok := referencedTypeName(value); ok {
+			refs = append(refs, ref)
+		}
+	}
+
+	if err := e.emitImports(fs, out, file, name, refs); err != nil {
+		return "", err
 	}
 
-	err := structFmt.Execute(out, args)
+	err = structFmt.Execute(out, args)
 	return name, err
 }
 
-func (e *Emitter) EmitValues(out io.Writer, values codegen.Values) (string, error) {
-	name := "Default"
-	for _, s := range values.Path.Segments {
-		if s.Elements {
-			name = name + "Element"
-		} else if s.Variants {
-			name = name + "Variant"
-		} else if s.Values {
-			name = name + "Value"
-		} else {
-			name = name + s.Property
-		}
+func (e *Emitter) EmitValues(fs codegen.FileSystem, values codegen.Values) (string, error) {
+	name := typeName(values.Path, values.Name)
+	file := fileName(values.Path, values.Name)
+
+	out, err := fs.OpenFile(file)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	var refs []string
+	if ref, ok := referencedTypeName(values.Values); ok {
+		refs = append(refs, ref)
+	}
+	if err := e.emitImports(fs, out, file, name, refs); err != nil {
+		return "", err
 	}
 
 	args := valueArgs{
-		Name:   name,
-		Values: values.Values,
+		Name:        name,
+		Description: values.Description,
+		Values:      values.Values,
 	}
 
-	err := valuesFmt.Execute(out, args)
+	err = valuesFmt.Execute(out, args)
 	return name, err
 }
 
-func (e *Emitter) EmitVariant(out io.Writer, variant codegen.Variant) (string, error) {
-	name := "Default"
-	for _, s := range variant.Path.Segments {
-		if s.Elements {
-			name = name + "Element"
-		} else if s.Variants {
-			name = name + "Variant"
-		} else if s.Values {
-			name = name + "Value"
-		} else {
-			name = name + s.Property
-		}
+func (e *Emitter) EmitVariant(fs codegen.FileSystem, variant codegen.Variant) (string, error) {
+	name := typeName(variant.Path, variant.Name)
+	file := fileName(variant.Path, variant.Name)
+
+	out, err := fs.OpenFile(file)
+	if err != nil {
+		return "", err
 	}
+	defer out.Close()
 
 	args := variantArgs{
-		Name:       name,
-		TagName:    variant.TagName,
-		TagValue:   variant.TagValue,
-		Properties: []structArgsProperties{},
+		Name:        name,
+		Description: variant.Description,
+		TagName:     variant.TagName,
+		TagValue:    variant.TagValue,
+		Properties:  []structArgsProperties{},
 	}
 
+	var refs []string
+
 	for key, value := range variant.RequiredProperties {
 		args.Properties = append(args.Properties, structArgsProperties{
 			Name: key,
 			Type: value,
 		})
+		if ref, ok := referencedTypeName(value); ok {
+			refs = append(refs, ref)
+		}
 	}
 
 	for key, value := range variant.OptionalProperties {
@@ -220,31 +401,442 @@ func (e *Emitter) EmitVariant(out io.Writer, variant codegen.Variant) (string, e
 			Name: fmt.Sprintf("%s?", key),
 			Type: value,
 		})
+		if ref, ok := referencedTypeName(value); ok {
+			refs = append(refs, ref)
+		}
 	}
 
-	err := structFmt.Execute(out, args)
+	if err := e.emitImports(fs, out, file, name, refs); err != nil {
+		return "", err
+	}
+
+	err = variantFmt.Execute(out, args)
 	return name, err
 }
 
-func (e *Emitter) EmitUnion(out io.Writer, union codegen.Union) (string, error) {
-	name := "Default"
-	for _, s := range union.Path.Segments {
-		if s.Elements {
-			name = name + "Element"
-		} else if s.Variants {
-			name = name + "Variant"
-		} else if s.Values {
-			name = name + "Value"
-		} else {
-			name = name + s.Property
-		}
+func (e *Emitter) EmitUnion(fs codegen.FileSystem, union codegen.Union) (string, error) {
+	name := typeName(union.Path, union.Name)
+	file := fileName(union.Path, union.Name)
+
+	out, err := fs.OpenFile(file)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	if err := e.emitImports(fs, out, file, name, union.Variants); err != nil {
+		return "", err
 	}
 
 	args := unionArgs{
+		Name:        name,
+		Description: union.Description,
+		Variants:    union.Variants,
+	}
+
+	err = unionFmt.Execute(out, args)
+	return name, err
+}
+
+// EmitFormattedPrimitive implements codegen.FormatEmitter, rendering the
+// built-in formats as a branded string type. Callers wanting a different
+// representation should register their own handler via
+// Encoder.RegisterFormat instead.
+func (e *Emitter) EmitFormattedPrimitive(fs codegen.FileSystem, base codegen.PrimitiveKind, format string) (string, error) {
+	name, ok := builtinFormats[format]
+	if !ok {
+		return "", fmt.Errorf("typescript: no built-in handler for format %#v; register one with Encoder.RegisterFormat", format)
+	}
+
+	out, err := fs.OpenFile(name + ".ts")
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	args := formatArgs{Name: name, Format: format}
+	err = formatFmt.Execute(out, args)
+	return args.Name, err
+}
+
+// validatorPreamble is written once per file, before that file's first
+// validator function, and declares the error type and primitive validators
+// every generated validator function relies on. It has no runtime
+// dependency of its own.
+var validatorPreamble = template.Must(template.New("validatorPreamble").Parse(`
+{{- /* */ -}}
+export interface ValidationError {
+	instancePath: string;
+	schemaPath: string;
+}
+
+function __jvcValidateEmpty(value: unknown, instancePath: string, schemaPath: string): ValidationError[] {
+	return [];
+}
+
+function __jvcValidateNull(value: unknown, instancePath: string, schemaPath: string): ValidationError[] {
+	return value === null ? [] : [{ instancePath, schemaPath }];
+}
+
+function __jvcValidateBoolean(value: unknown, instancePath: string, schemaPath: string): ValidationError[] {
+	return typeof value === "boolean" ? [] : [{ instancePath, schemaPath }];
+}
+
+function __jvcValidateNumber(value: unknown, instancePath: string, schemaPath: string): ValidationError[] {
+	return typeof value === "number" ? [] : [{ instancePath, schemaPath }];
+}
+
+function __jvcValidateString(value: unknown, instancePath: string, schemaPath: string): ValidationError[] {
+	return typeof value === "string" ? [] : [{ instancePath, schemaPath }];
+}
+
+function __jvcValidateNullable(inner: (value: unknown, instancePath: string, schemaPath: string) => ValidationError[]): (value: unknown, instancePath: string, schemaPath: string) => ValidationError[] {
+	return (value, instancePath, schemaPath) => (value === null ? [] : inner(value, instancePath, schemaPath));
+}
+`))
+
+var (
+	arrayValidatorFmt = template.Must(template.New("arrayValidator").Parse(`
+{{- /* */ -}}
+export function {{ .Name }}(value: unknown, instancePath = "", schemaPath = ""): ValidationError[] {
+	if (!Array.isArray(value)) {
+		return [{ instancePath, schemaPath }];
+	}
+
+	let errors: ValidationError[] = [];
+	value.forEach((element, index) => {
+		errors = errors.concat({{ .Elements }}(element, ` + "`${instancePath}/${index}`" + `, ` + "`${schemaPath}/elements`" + `));
+	});
+
+	return errors;
+}
+`))
+
+	structValidatorFmt = template.Must(template.New("structValidator").Parse(`
+{{- /* */ -}}
+export function {{ .Name }}(value: unknown, instancePath = "", schemaPath = ""): ValidationError[] {
+	if (typeof value !== "object" || value === null || Array.isArray(value)) {
+		return [{ instancePath, schemaPath }];
+	}
+
+	const obj = value as Record<string, unknown>;
+	let errors: ValidationError[] = [];
+{{ range .Properties }}
+{{ if .Optional }}	if (obj["{{ .JSONName }}"] !== undefined) {
+		errors = errors.concat({{ .Validator }}(obj["{{ .JSONName }}"], ` + "`${instancePath}/" + `{{ .JSONName }}` + "`" + `, ` + "`${schemaPath}/" + `{{ .SchemaPath }}` + "`" + `));
+	}
+{{ else }}	errors = errors.concat({{ .Validator }}(obj["{{ .JSONName }}"], ` + "`${instancePath}/" + `{{ .JSONName }}` + "`" + `, ` + "`${schemaPath}/" + `{{ .SchemaPath }}` + "`" + `));
+{{ end -}}
+{{- end }}
+
+	return errors;
+}
+`))
+
+	valuesValidatorFmt = template.Must(template.New("valuesValidator").Parse(`
+{{- /* */ -}}
+export function {{ .Name }}(value: unknown, instancePath = "", schemaPath = ""): ValidationError[] {
+	if (typeof value !== "object" || value === null || Array.isArray(value)) {
+		return [{ instancePath, schemaPath }];
+	}
+
+	const obj = value as Record<string, unknown>;
+	let errors: ValidationError[] = [];
+	for (const key of Object.keys(obj)) {
+		errors = errors.concat({{ .Values }}(obj[key], ` + "`${instancePath}/${key}`" + `, ` + "`${schemaPath}/values`" + `));
+	}
+
+	return errors;
+}
+`))
+
+	variantValidatorFmt = template.Must(template.New("variantValidator").Parse(`
+{{- /* */ -}}
+export function {{ .Name }}(value: unknown, instancePath = "", schemaPath = ""): ValidationError[] {
+	if (typeof value !== "object" || value === null || Array.isArray(value)) {
+		return [{ instancePath, schemaPath }];
+	}
+
+	const obj = value as Record<string, unknown>;
+	if (obj["{{ .TagName }}"] !== "{{ .TagValue }}") {
+		return [{ instancePath: ` + "`${instancePath}/" + `{{ .TagName }}` + "`" + `, schemaPath }];
+	}
+
+	let errors: ValidationError[] = [];
+{{ range .Properties }}
+{{ if .Optional }}	if (obj["{{ .JSONName }}"] !== undefined) {
+		errors = errors.concat({{ .Validator }}(obj["{{ .JSONName }}"], ` + "`${instancePath}/" + `{{ .JSONName }}` + "`" + `, ` + "`${schemaPath}/" + `{{ .SchemaPath }}` + "`" + `));
+	}
+{{ else }}	errors = errors.concat({{ .Validator }}(obj["{{ .JSONName }}"], ` + "`${instancePath}/" + `{{ .JSONName }}` + "`" + `, ` + "`${schemaPath}/" + `{{ .SchemaPath }}` + "`" + `));
+{{ end -}}
+{{- end }}
+
+	return errors;
+}
+`))
+
+	unionValidatorFmt = template.Must(template.New("unionValidator").Parse(`
+{{- /* */ -}}
+export function {{ .Name }}(value: unknown, instancePath = "", schemaPath = ""): ValidationError[] {
+	if (typeof value !== "object" || value === null || Array.isArray(value)) {
+		return [{ instancePath, schemaPath }];
+	}
+
+	const obj = value as Record<string, unknown>;
+	switch (obj["{{ .TagName }}"]) {
+{{- range .Variants }}
+	case "{{ .TagValue }}":
+		return {{ .Validator }}(value, instancePath, schemaPath);
+{{- end }}
+	default:
+		return [{ instancePath: ` + "`${instancePath}/" + `{{ .TagName }}` + "`" + `, schemaPath }];
+	}
+}
+`))
+)
+
+type validatorArgsProperty struct {
+	JSONName   string
+	SchemaPath string
+	Validator  string
+	Optional   bool
+}
+
+type validatorArgsVariant struct {
+	TagValue  string
+	Validator string
+}
+
+func (e *Emitter) ensureValidatorPreamble(fs codegen.FileSystem, out io.Writer, file string) error {
+	return e.preamble.Ensure(fs, file, func() error {
+		return validatorPreamble.Execute(out, nil)
+	})
+}
+
+// WrapNullableValidator implements codegen.ValidatorEmitter, wrapping inner
+// in the __jvcValidateNullable preamble helper so that null is accepted in
+// addition to whatever inner itself validates.
+func (e *Emitter) WrapNullableValidator(inner string) string {
+	return fmt.Sprintf("__jvcValidateNullable(%s)", inner)
+}
+
+func (e *Emitter) EmitPrimitiveValidator(kind codegen.PrimitiveKind) (string, error) {
+	switch kind {
+	case codegen.PrimitiveKindEmpty:
+		return "__jvcValidateEmpty", nil
+	case codegen.PrimitiveKindNull:
+		return "__jvcValidateNull", nil
+	case codegen.PrimitiveKindBoolean:
+		return "__jvcValidateBoolean", nil
+	case codegen.PrimitiveKindNumber:
+		return "__jvcValidateNumber", nil
+	case codegen.PrimitiveKindString:
+		return "__jvcValidateString", nil
+	default:
+		return "", fmt.Errorf("typescript: unknown primitive kind %v", kind)
+	}
+}
+
+func (e *Emitter) EmitArrayValidator(fs codegen.FileSystem, array codegen.Array) (string, error) {
+	file := fileName(array.Path, array.Name)
+	out, err := fs.OpenFile(file)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	if err := e.ensureValidatorPreamble(fs, out, file); err != nil {
+		return "", err
+	}
+
+	name := "validate" + typeName(array.Path, array.Name)
+
+	if err := e.emitImports(fs, out, file, name, validatorRefPattern.FindAllString(array.Elements, -1)); err != nil {
+		return "", err
+	}
+
+	err = arrayValidatorFmt.Execute(out, struct {
+		Name     string
+		Elements string
+	}{
 		Name:     name,
-		Variants: union.Variants,
+		Elements: array.Elements,
+	})
+
+	return name, err
+}
+
+func (e *Emitter) EmitStructValidator(fs codegen.FileSystem, strukt codegen.Struct) (string, error) {
+	file := fileName(strukt.Path, strukt.Name)
+	out, err := fs.OpenFile(file)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	if err := e.ensureValidatorPreamble(fs, out, file); err != nil {
+		return "", err
+	}
+
+	name := "validate" + typeName(strukt.Path, strukt.Name)
+
+	properties := []validatorArgsProperty{}
+	for key, validator := range strukt.RequiredProperties {
+		properties = append(properties, validatorArgsProperty{
+			JSONName:   key,
+			SchemaPath: "properties/" + key,
+			Validator:  validator,
+		})
+	}
+
+	for key, validator := range strukt.OptionalProperties {
+		properties = append(properties, validatorArgsProperty{
+			JSONName:   key,
+			SchemaPath: "optionalProperties/" + key,
+			Validator:  validator,
+			Optional:   true,
+		})
+	}
+
+	var refs []string
+	for _, property := range properties {
+		refs = append(refs, validatorRefPattern.FindAllString(property.Validator, -1)...)
+	}
+	if err := e.emitImports(fs, out, file, name, refs); err != nil {
+		return "", err
+	}
+
+	err = structValidatorFmt.Execute(out, struct {
+		Name       string
+		Properties []validatorArgsProperty
+	}{
+		Name:       name,
+		Properties: properties,
+	})
+
+	return name, err
+}
+
+func (e *Emitter) EmitValuesValidator(fs codegen.FileSystem, values codegen.Values) (string, error) {
+	file := fileName(values.Path, values.Name)
+	out, err := fs.OpenFile(file)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	if err := e.ensureValidatorPreamble(fs, out, file); err != nil {
+		return "", err
 	}
 
-	err := unionFmt.Execute(out, args)
+	name := "validate" + typeName(values.Path, values.Name)
+
+	if err := e.emitImports(fs, out, file, name, validatorRefPattern.FindAllString(values.Values, -1)); err != nil {
+		return "", err
+	}
+
+	err = valuesValidatorFmt.Execute(out, struct {
+		Name   string
+		Values string
+	}{
+		Name:   name,
+		Values: values.Values,
+	})
+
+	return name, err
+}
+
+func (e *Emitter) EmitVariantValidator(fs codegen.FileSystem, variant codegen.Variant) (string, error) {
+	file := fileName(variant.Path, variant.Name)
+	out, err := fs.OpenFile(file)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	if err := e.ensureValidatorPreamble(fs, out, file); err != nil {
+		return "", err
+	}
+
+	name := "validate" + typeName(variant.Path, variant.Name)
+
+	properties := []validatorArgsProperty{}
+	for key, validator := range variant.RequiredProperties {
+		properties = append(properties, validatorArgsProperty{
+			JSONName:   key,
+			SchemaPath: "properties/" + key,
+			Validator:  validator,
+		})
+	}
+
+	for key, validator := range variant.OptionalProperties {
+		properties = append(properties, validatorArgsProperty{
+			JSONName:   key,
+			SchemaPath: "optionalProperties/" + key,
+			Validator:  validator,
+			Optional:   true,
+		})
+	}
+
+	var refs []string
+	for _, property := range properties {
+		refs = append(refs, validatorRefPattern.FindAllString(property.Validator, -1)...)
+	}
+	if err := e.emitImports(fs, out, file, name, refs); err != nil {
+		return "", err
+	}
+
+	err = variantValidatorFmt.Execute(out, struct {
+		Name       string
+		TagName    string
+		TagValue   string
+		Properties []validatorArgsProperty
+	}{
+		Name:       name,
+		TagName:    variant.TagName,
+		TagValue:   variant.TagValue,
+		Properties: properties,
+	})
+
+	return name, err
+}
+
+func (e *Emitter) EmitUnionValidator(fs codegen.FileSystem, union codegen.Union) (string, error) {
+	file := fileName(union.Path, union.Name)
+	out, err := fs.OpenFile(file)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	if err := e.ensureValidatorPreamble(fs, out, file); err != nil {
+		return "", err
+	}
+
+	name := "validate" + typeName(union.Path, union.Name)
+
+	variants := []validatorArgsVariant{}
+	for tag, validator := range union.VariantsByTag {
+		variants = append(variants, validatorArgsVariant{TagValue: tag, Validator: validator})
+	}
+
+	var refs []string
+	for _, variant := range variants {
+		refs = append(refs, validatorRefPattern.FindAllString(variant.Validator, -1)...)
+	}
+	if err := e.emitImports(fs, out, file, name, refs); err != nil {
+		return "", err
+	}
+
+	err = unionValidatorFmt.Execute(out, struct {
+		Name     string
+		TagName  string
+		Variants []validatorArgsVariant
+	}{
+		Name:     name,
+		TagName:  union.TagName,
+		Variants: variants,
+	})
+
 	return name, err
 }